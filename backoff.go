@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+	"time"
+)
+
+const maxFetchAttempts = 3
+
+// retryWithBackoff retries fn on error with exponential backoff and jitter,
+// giving up after maxFetchAttempts. Intended for transient failures such as
+// network errors or 5xx responses.
+func retryWithBackoff(label string, fn func() error) error {
+	delay := time.Second
+	var err error
+
+	for attempt := 1; attempt <= maxFetchAttempts; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if attempt == maxFetchAttempts {
+			break
+		}
+
+		sleep := delay + time.Duration(rand.Int63n(int64(delay)))
+		log.Printf("%s failed (attempt %d/%d): %v, retrying in %s", label, attempt, maxFetchAttempts, err, sleep)
+		time.Sleep(sleep)
+		delay *= 2
+	}
+
+	return err
+}
@@ -0,0 +1,144 @@
+// Package filter decides whether a fetched release is noisy enough to
+// suppress: prereleases, patch-only bumps, titles that don't match a
+// configured pattern, or repos that are simply posting too often.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+var prereleaseMarkers = []string{"-rc", "-beta", "-alpha", "-preview", "-pre"}
+
+// Release is the minimal information filter needs about a release; it
+// doesn't depend on any particular feed or API representation.
+type Release struct {
+	GUID       string
+	Title      string
+	Categories []string
+}
+
+// Rule is a compiled set of filter settings for one repo (or the default
+// applied to repos without an override).
+type Rule struct {
+	SkipPrereleases bool
+	MinBump         string // "", "minor" or "major"
+	TitleMatch      *regexp.Regexp
+	TitleExclude    *regexp.Regexp
+	MaxPerDay       int
+}
+
+// NewRule compiles a Rule from settings.json-style string fields.
+func NewRule(skipPrereleases bool, minBump, titleMatch, titleExclude string, maxPerDay int) (Rule, error) {
+	rule := Rule{SkipPrereleases: skipPrereleases, MinBump: minBump, MaxPerDay: maxPerDay}
+
+	if titleMatch != "" {
+		re, err := regexp.Compile(titleMatch)
+		if err != nil {
+			return Rule{}, fmt.Errorf("unable to compile title_match: %v", err)
+		}
+		rule.TitleMatch = re
+	}
+
+	if titleExclude != "" {
+		re, err := regexp.Compile(titleExclude)
+		if err != nil {
+			return Rule{}, fmt.Errorf("unable to compile title_exclude: %v", err)
+		}
+		rule.TitleExclude = re
+	}
+
+	return rule, nil
+}
+
+// Allow reports whether candidate passes rule. previous is the next-older
+// release already known for the same repo (the zero value if unknown), used
+// to assess a semver bump; seenToday is how many releases for this repo have
+// already been let through in the current rate-limit window.
+func Allow(rule Rule, candidate, previous Release, seenToday int) (bool, string) {
+	if rule.SkipPrereleases && isPrerelease(candidate) {
+		return false, "prerelease"
+	}
+
+	if rule.TitleMatch != nil && !rule.TitleMatch.MatchString(candidate.Title) {
+		return false, "title does not match title_match"
+	}
+
+	if rule.TitleExclude != nil && rule.TitleExclude.MatchString(candidate.Title) {
+		return false, "title matches title_exclude"
+	}
+
+	if rule.MinBump != "" && !bumpsAtLeast(rule.MinBump, candidate, previous) {
+		return false, fmt.Sprintf("not a %s version bump", rule.MinBump)
+	}
+
+	if rule.MaxPerDay > 0 && seenToday >= rule.MaxPerDay {
+		return false, "rate limit reached"
+	}
+
+	return true, ""
+}
+
+func isPrerelease(r Release) bool {
+	lower := strings.ToLower(r.Title + " " + r.GUID)
+	for _, marker := range prereleaseMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+
+	for _, category := range r.Categories {
+		c := strings.ToLower(category)
+		if strings.Contains(c, "prerelease") || strings.Contains(c, "pre-release") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bumpsAtLeast reports whether candidate is at least a minBump ("minor" or
+// "major") version step up from previous. If either release's version can't
+// be parsed, the bump can't be assessed and the release is let through.
+func bumpsAtLeast(minBump string, candidate, previous Release) bool {
+	candidateVersion := extractVersion(candidate)
+	previousVersion := extractVersion(previous)
+	if candidateVersion == "" || previousVersion == "" {
+		return true
+	}
+
+	candidateMajor, candidateMinor := semver.Major(candidateVersion), semver.MajorMinor(candidateVersion)
+	previousMajor, previousMinor := semver.Major(previousVersion), semver.MajorMinor(previousVersion)
+
+	switch minBump {
+	case "major":
+		return candidateMajor != previousMajor
+	case "minor":
+		return candidateMajor != previousMajor || candidateMinor != previousMinor
+	default:
+		return true
+	}
+}
+
+var versionPattern = regexp.MustCompile(`v?\d+\.\d+(\.\d+)?(-[0-9A-Za-z.]+)?`)
+
+// extractVersion pulls a semver-looking substring out of a release's GUID or
+// title and gives it a "v" prefix, returning "" if none is found.
+func extractVersion(r Release) string {
+	for _, candidate := range []string{r.GUID, r.Title} {
+		match := versionPattern.FindString(candidate)
+		if match == "" {
+			continue
+		}
+		if !strings.HasPrefix(match, "v") {
+			match = "v" + match
+		}
+		if semver.IsValid(match) {
+			return match
+		}
+	}
+	return ""
+}
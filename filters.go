@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/n-at/releases-stream-v2/filter"
+)
+
+// resolveFilterRule compiles the filter.Rule to apply to a source: its
+// per-repo override from repo_filters if present, otherwise default_filter.
+func resolveFilterRule(s Settings, sourceKey string) (filter.Rule, error) {
+	cfg := s.DefaultFilter
+	if override, ok := s.RepoFilters[sourceKey]; ok {
+		cfg = override
+	}
+
+	return filter.NewRule(cfg.SkipPrereleases, cfg.MinBump, cfg.TitleMatch, cfg.TitleExclude, cfg.MaxPerDay)
+}
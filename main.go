@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
@@ -9,19 +10,52 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/mmcdole/gofeed"
-	"gopkg.in/gomail.v2"
+	"github.com/n-at/releases-stream-v2/store"
 )
 
+const defaultDatabasePath = "releases.db"
+
 const PAGE_MAX_LENGTH = 512 * 1024
 
 type Settings struct {
-	UserName     string `json:"username"`
-	Token        string `json:"token"`
+	UserName            string                  `json:"username"`
+	Token               string                  `json:"token"`
+	GitLabToken         string                  `json:"gitlab_token"`
+	DockerHubToken      string                  `json:"dockerhub_token"`
+	Tracked             []string                `json:"tracked"`
+	PollIntervalMinutes int                     `json:"poll_interval_minutes"`
+	ListenAddr          string                  `json:"listen_addr"`
+	FetchConcurrency    int                     `json:"fetch_concurrency"`
+	FetchRequestsPerSec float64                 `json:"fetch_requests_per_second"`
+	DatabasePath        string                  `json:"database_path"`
+	Notifiers           []NotifierConfig        `json:"notifiers"`
+	DefaultFilter       FilterConfig            `json:"default_filter"`
+	RepoFilters         map[string]FilterConfig `json:"repo_filters"`
+}
+
+// FilterConfig describes the noise-suppression rule applied to one repo (or
+// to every repo without its own entry in repo_filters).
+type FilterConfig struct {
+	SkipPrereleases bool   `json:"skip_prereleases"`
+	MinBump         string `json:"min_bump"` // "", "minor" or "major"
+	TitleMatch      string `json:"title_match"`
+	TitleExclude    string `json:"title_exclude"`
+	MaxPerDay       int    `json:"max_per_day"`
+}
+
+// NotifierConfig describes a single configured notify.Notifier. Type selects
+// which backend is built; the fields relevant to other backends are ignored.
+type NotifierConfig struct {
+	Type string `json:"type"`
+
 	MailFrom     string `json:"mail_from"`
 	MailTo       string `json:"mail_to"`
 	MailHost     string `json:"mail_host"`
@@ -29,6 +63,20 @@ type Settings struct {
 	MailSSL      bool   `json:"mail_ssl"`
 	MailUsername string `json:"mail_username"`
 	MailPassword string `json:"mail_password"`
+
+	WebhookUrl   string `json:"webhook_url"`
+	WebhookToken string `json:"webhook_token"`
+
+	TelegramBotToken string `json:"telegram_bot_token"`
+	TelegramChatId   string `json:"telegram_chat_id"`
+
+	MatrixHomeserver  string `json:"matrix_homeserver"`
+	MatrixAccessToken string `json:"matrix_access_token"`
+	MatrixRoomId      string `json:"matrix_room_id"`
+
+	NtfyUrl   string `json:"ntfy_url"`
+	NtfyTopic string `json:"ntfy_topic"`
+	NtfyToken string `json:"ntfy_token"`
 }
 
 type Repository struct {
@@ -45,6 +93,7 @@ type Repository struct {
 }
 
 type Release struct {
+	SourceKey  string
 	Repository *Repository
 	FeedItem   *gofeed.Item
 }
@@ -70,58 +119,66 @@ func main() {
 		log.Fatalf("unable to load mail template: %v", err)
 	}
 
-	repositories, err := getStarredRepos(s.UserName, s.Token)
-	if err != nil {
-		log.Fatalf("unable to get starred repos: %v", err)
+	databasePath := s.DatabasePath
+	if databasePath == "" {
+		databasePath = defaultDatabasePath
 	}
 
-	latestIds := readLatestIds()
+	st, err := store.Open(databasePath)
+	if err != nil {
+		log.Fatalf("unable to open store: %v", err)
+	}
+	defer st.Close()
 
-	var releases []Release
+	if err := migrateLatestIds(s, st); err != nil {
+		log.Printf("unable to migrate latest.json: %v", err)
+	}
 
-	for _, repository := range repositories {
-		log.Printf("reading releases for %s...", repository.FullName)
+	sch := NewScheduler(s, tpl, st)
 
-		releasesFeed, err := getLatestReleases(repository, latestIds[repository.FullName])
-		if err != nil {
-			log.Printf("unable to read releases for %s: %v", repository.FullName, err)
-			continue
-		}
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
 
-		log.Printf("read releases for %s: %d", repository.FullName, len(releasesFeed))
+	httpServer := startHttpServer(s, sch)
 
-		if len(releasesFeed) > 0 {
-			latestIds[repository.FullName] = releasesFeed[0].GUID
-		}
+	sch.Run(ctx)
 
-		for _, releaseFeedItem := range releasesFeed {
-			releases = append(releases, Release{
-				Repository: &repository,
-				FeedItem:   releaseFeedItem,
-			})
-		}
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("unable to shut down http server: %v", err)
 	}
+}
 
-	defer writeLatestIds(latestIds)
+func startHttpServer(s Settings, sch *Scheduler) *http.Server {
+	mux := http.NewServeMux()
 
-	pages := splitReleasesByPages(releases)
-	log.Printf("got pages to send: %d", len(pages))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
 
-	for _, page := range pages {
-		pageRepos := extractPageRepositories(page)
-		sb := strings.Builder{}
-		err := tpl.ExecuteTemplate(&sb, "mail.html", map[string]any{
-			"repositories": pageRepos,
-		})
-		if err != nil {
-			log.Printf("unable to render page: %v", err)
-			continue
-		}
+	mux.HandleFunc("/trigger", func(w http.ResponseWriter, r *http.Request) {
+		sch.Trigger()
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("triggered"))
+	})
 
-		if err := sendMail(s, sb.String()); err != nil {
-			log.Printf("unable to send mail: %v", err)
-		}
+	addr := s.ListenAddr
+	if addr == "" {
+		addr = ":8080"
 	}
+
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		log.Printf("listening on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("http server error: %v", err)
+		}
+	}()
+
+	return server
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -141,36 +198,46 @@ func readSettings() (Settings, error) {
 	return s, nil
 }
 
-func readLatestIds() map[string]string {
-	ids := make(map[string]string)
-
-	bytes, err := os.ReadFile("latest.json")
-	if err != nil {
-		log.Printf("unable to read latest.json: %v", err)
-		return ids
+// migrateLatestIds imports the old flat latest.json cursor file into the
+// store, once. It is a no-op if latest.json doesn't exist.
+func migrateLatestIds(s Settings, st *store.Store) error {
+	notifiers := make([]string, len(s.Notifiers))
+	for i, cfg := range s.Notifiers {
+		notifiers[i] = cfg.Type
 	}
 
-	if err := json.Unmarshal(bytes, &ids); err != nil {
-		log.Printf("unable to read latest.json: %v", err)
-		return ids
-	}
-
-	return ids
+	return store.MigrateLatestJSON(st, "latest.json", notifiers)
 }
 
-func writeLatestIds(ids map[string]string) {
-	bytes, err := json.Marshal(ids)
-	if err != nil {
-		log.Printf("unable to marshal latest.json: %d", err)
-		return
+///////////////////////////////////////////////////////////////////////////////
+
+// buildSources assembles the full list of tracked sources: the user's GitHub
+// starred repos plus whatever is declared in settings.json's "tracked" list.
+func buildSources(s Settings) ([]Source, error) {
+	var sources []Source
+
+	if s.UserName != "" {
+		repositories, err := getStarredRepos(s.UserName, s.Token)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get starred repos: %v", err)
+		}
+
+		for _, repository := range repositories {
+			sources = append(sources, newGithubSource(repository))
+		}
 	}
 
-	if err := os.WriteFile("latest.json", bytes, 0666); err != nil {
-		log.Printf("unable to write latest.json: %v", err)
+	for _, spec := range s.Tracked {
+		source, err := ParseTrackedSource(spec, s)
+		if err != nil {
+			log.Printf("unable to parse tracked entry: %v", err)
+			continue
+		}
+		sources = append(sources, source)
 	}
-}
 
-///////////////////////////////////////////////////////////////////////////////
+	return sources, nil
+}
 
 func getStarredRepos(username, token string) ([]Repository, error) {
 	var r []Repository
@@ -215,7 +282,42 @@ func getStarredRepos(username, token string) ([]Repository, error) {
 	return r, nil
 }
 
+// sendRequest issues the request, retrying transient (5xx/network) failures
+// with backoff. If GitHub rate-limits the request, it waits out
+// the limit and re-dispatches rather than returning the stale rate-limited
+// response: the caller never sees a 403, only the eventual real result.
 func sendRequest(url, token string) (*http.Response, error) {
+	var resp *http.Response
+
+	for attempt := 1; attempt <= maxFetchAttempts; attempt++ {
+		err := retryWithBackoff("request to "+url, func() error {
+			r, err := doRequest(url, token)
+			if err != nil {
+				return err
+			}
+			if r.StatusCode >= http.StatusInternalServerError {
+				r.Body.Close()
+				return fmt.Errorf("bad response status: %v", r.Status)
+			}
+			resp = r
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !githubRateLimited(resp) {
+			return resp, nil
+		}
+
+		waitForGithubRateLimit(resp)
+		resp.Body.Close()
+	}
+
+	return nil, fmt.Errorf("request to %s: still rate limited after %d attempts", url, maxFetchAttempts)
+}
+
+func doRequest(url, token string) (*http.Response, error) {
 	client := &http.Client{}
 	client.Timeout = 15 * time.Second
 
@@ -231,6 +333,49 @@ func sendRequest(url, token string) (*http.Response, error) {
 	return client.Do(req)
 }
 
+// githubRateLimited reports whether resp indicates GitHub rejected the
+// request for rate-limiting reasons (X-RateLimit-Remaining: 0, or an abuse
+// detection Retry-After), as opposed to any other non-5xx status.
+func githubRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		seconds, err := strconv.Atoi(retryAfter)
+		return err == nil && seconds > 0
+	}
+	return resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// waitForGithubRateLimit sleeps until GitHub's rate limit resets, if the
+// response indicates we've run out of requests (X-RateLimit-Remaining: 0)
+// or GitHub asked us to back off (Retry-After).
+func waitForGithubRateLimit(resp *http.Response) {
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+			log.Printf("told to retry after %ds", seconds)
+			time.Sleep(time.Duration(seconds) * time.Second)
+		}
+		return
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+
+	resetHeader := resp.Header.Get("X-RateLimit-Reset")
+	resetUnix, err := strconv.ParseInt(resetHeader, 10, 64)
+	if err != nil {
+		return
+	}
+
+	wait := time.Until(time.Unix(resetUnix, 0))
+	if wait > 0 {
+		log.Printf("rate limit exhausted, sleeping %s", wait)
+		time.Sleep(wait)
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 func getLatestReleases(r Repository, latestId string) ([]*gofeed.Item, error) {
@@ -320,17 +465,3 @@ func loadMailTemplate() (*template.Template, error) {
 	return tpl, nil
 }
 
-func sendMail(s Settings, text string) error {
-	msg := gomail.NewMessage()
-	msg.SetHeader("From", s.MailFrom)
-	msg.SetHeader("To", s.MailTo)
-	msg.SetHeader("Subject", "New GitHub Releases")
-	msg.SetBody("text/html", text)
-
-	d := gomail.NewDialer(s.MailHost, s.MailPort, s.MailUsername, s.MailPassword)
-	if s.MailSSL {
-		d.SSL = true
-	}
-
-	return d.DialAndSend(msg)
-}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/n-at/releases-stream-v2/notify"
+)
+
+// buildNotifiers constructs the configured notify.Notifier set from
+// settings.json's "notifiers" list.
+func buildNotifiers(s Settings) ([]notify.Notifier, error) {
+	var notifiers []notify.Notifier
+
+	for _, cfg := range s.Notifiers {
+		switch cfg.Type {
+		case "smtp":
+			notifiers = append(notifiers, notify.NewSmtpNotifier(notify.SmtpConfig{
+				From:     cfg.MailFrom,
+				To:       cfg.MailTo,
+				Host:     cfg.MailHost,
+				Port:     cfg.MailPort,
+				SSL:      cfg.MailSSL,
+				Username: cfg.MailUsername,
+				Password: cfg.MailPassword,
+			}))
+		case "webhook":
+			notifiers = append(notifiers, notify.NewWebhookNotifier(notify.WebhookConfig{
+				Url:   cfg.WebhookUrl,
+				Token: cfg.WebhookToken,
+			}))
+		case "telegram":
+			notifiers = append(notifiers, notify.NewTelegramNotifier(notify.TelegramConfig{
+				BotToken: cfg.TelegramBotToken,
+				ChatId:   cfg.TelegramChatId,
+			}))
+		case "matrix":
+			notifiers = append(notifiers, notify.NewMatrixNotifier(notify.MatrixConfig{
+				Homeserver:  cfg.MatrixHomeserver,
+				AccessToken: cfg.MatrixAccessToken,
+				RoomId:      cfg.MatrixRoomId,
+			}))
+		case "ntfy":
+			notifiers = append(notifiers, notify.NewNtfyNotifier(notify.NtfyConfig{
+				Url:   cfg.NtfyUrl,
+				Topic: cfg.NtfyTopic,
+				Token: cfg.NtfyToken,
+			}))
+		default:
+			return nil, fmt.Errorf("unrecognized notifier type: %q", cfg.Type)
+		}
+	}
+
+	return notifiers, nil
+}
+
+// toNotifyRepositoryReleases adapts this package's RepositoryReleases (backed
+// by *gofeed.Item) into the source-agnostic form the notify package formats.
+func toNotifyRepositoryReleases(pageRepos []RepositoryReleases) []notify.RepositoryReleases {
+	result := make([]notify.RepositoryReleases, 0, len(pageRepos))
+
+	for _, rr := range pageRepos {
+		nrr := notify.RepositoryReleases{
+			Repository: notify.Repository{
+				Name:     rr.Repository.Name,
+				FullName: rr.Repository.FullName,
+				Url:      rr.Repository.Url,
+			},
+		}
+
+		for _, feedItem := range rr.FeedItems {
+			item := notify.Item{
+				Title:   feedItem.Title,
+				Link:    feedItem.Link,
+				Content: feedItem.Content,
+			}
+			if feedItem.PublishedParsed != nil {
+				item.Published = *feedItem.PublishedParsed
+			}
+			nrr.Items = append(nrr.Items, item)
+		}
+
+		result = append(result, nrr)
+	}
+
+	return result
+}
@@ -0,0 +1,28 @@
+package notify
+
+import "fmt"
+
+// formatMarkdown renders a page as Markdown, suitable for Telegram or Matrix.
+func formatMarkdown(page Page) string {
+	s := ""
+	for _, rr := range page.Repositories {
+		s += fmt.Sprintf("*%s*\n", rr.Repository.FullName)
+		for _, item := range rr.Items {
+			s += fmt.Sprintf("- [%s](%s)\n", item.Title, item.Link)
+		}
+		s += "\n"
+	}
+	return s
+}
+
+// formatPlaintext renders a page as plain text, suitable for ntfy.
+func formatPlaintext(page Page) string {
+	s := ""
+	for _, rr := range page.Repositories {
+		s += rr.Repository.FullName + "\n"
+		for _, item := range rr.Items {
+			s += fmt.Sprintf("  %s: %s\n", item.Title, item.Link)
+		}
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type MatrixConfig struct {
+	Homeserver  string
+	AccessToken string
+	RoomId      string
+}
+
+type matrixNotifier struct {
+	cfg MatrixConfig
+}
+
+func NewMatrixNotifier(cfg MatrixConfig) Notifier {
+	return &matrixNotifier{cfg: cfg}
+}
+
+func (n *matrixNotifier) Name() string {
+	return "matrix"
+}
+
+func (n *matrixNotifier) Send(page Page) error {
+	body := formatMarkdown(page)
+
+	payload, err := json.Marshal(map[string]string{
+		"msgtype": "m.text",
+		"body":    body,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to marshal payload: %v", err)
+	}
+
+	txnId := fmt.Sprintf("%d", time.Now().UnixNano())
+	apiUrl := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s", n.cfg.Homeserver, n.cfg.RoomId, txnId)
+
+	req, err := http.NewRequest("PUT", apiUrl, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+n.cfg.AccessToken)
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad response status: %v", resp.Status)
+	}
+
+	return nil
+}
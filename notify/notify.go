@@ -0,0 +1,42 @@
+// Package notify renders and delivers release pages to a configurable set of
+// backends (email, chat, webhooks, ...). Each backend formats the same data
+// in whatever register suits it: HTML for email, Markdown for chat clients,
+// plaintext for push notifications.
+package notify
+
+import "time"
+
+// Item is a single release, source-agnostic.
+type Item struct {
+	Title     string
+	Link      string
+	Content   string
+	Published time.Time
+}
+
+// Repository groups releases under the project they belong to.
+type Repository struct {
+	Name     string
+	FullName string
+	Url      string
+}
+
+type RepositoryReleases struct {
+	Repository Repository
+	Items      []Item
+}
+
+// Page is everything a Notifier needs to deliver one batch of releases. HTML
+// is pre-rendered (from mail.html) for backends that want it verbatim;
+// Repositories is the structured form for backends that format their own
+// message body.
+type Page struct {
+	HTML         string
+	Repositories []RepositoryReleases
+}
+
+// Notifier delivers a Page through one backend.
+type Notifier interface {
+	Name() string
+	Send(page Page) error
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type NtfyConfig struct {
+	Url   string
+	Topic string
+	Token string
+}
+
+type ntfyNotifier struct {
+	cfg NtfyConfig
+}
+
+func NewNtfyNotifier(cfg NtfyConfig) Notifier {
+	return &ntfyNotifier{cfg: cfg}
+}
+
+func (n *ntfyNotifier) Name() string {
+	return "ntfy"
+}
+
+func (n *ntfyNotifier) Send(page Page) error {
+	apiUrl := strings.TrimSuffix(n.cfg.Url, "/") + "/" + n.cfg.Topic
+
+	req, err := http.NewRequest("POST", apiUrl, strings.NewReader(formatPlaintext(page)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", "New Releases")
+	if n.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.cfg.Token)
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad response status: %v", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package notify
+
+import "gopkg.in/gomail.v2"
+
+type SmtpConfig struct {
+	From     string
+	To       string
+	Host     string
+	Port     int
+	SSL      bool
+	Username string
+	Password string
+}
+
+type smtpNotifier struct {
+	cfg SmtpConfig
+}
+
+func NewSmtpNotifier(cfg SmtpConfig) Notifier {
+	return &smtpNotifier{cfg: cfg}
+}
+
+func (n *smtpNotifier) Name() string {
+	return "smtp"
+}
+
+func (n *smtpNotifier) Send(page Page) error {
+	msg := gomail.NewMessage()
+	msg.SetHeader("From", n.cfg.From)
+	msg.SetHeader("To", n.cfg.To)
+	msg.SetHeader("Subject", "New Releases")
+	msg.SetBody("text/html", page.HTML)
+
+	d := gomail.NewDialer(n.cfg.Host, n.cfg.Port, n.cfg.Username, n.cfg.Password)
+	if n.cfg.SSL {
+		d.SSL = true
+	}
+
+	return d.DialAndSend(msg)
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type TelegramConfig struct {
+	BotToken string
+	ChatId   string
+}
+
+type telegramNotifier struct {
+	cfg TelegramConfig
+}
+
+func NewTelegramNotifier(cfg TelegramConfig) Notifier {
+	return &telegramNotifier{cfg: cfg}
+}
+
+func (n *telegramNotifier) Name() string {
+	return "telegram"
+}
+
+func (n *telegramNotifier) Send(page Page) error {
+	apiUrl := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", n.cfg.BotToken)
+
+	form := url.Values{}
+	form.Set("chat_id", n.cfg.ChatId)
+	form.Set("text", formatMarkdown(page))
+	form.Set("parse_mode", "Markdown")
+	form.Set("disable_web_page_preview", "true")
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.PostForm(apiUrl, form)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Description string `json:"description"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("bad response status: %v: %s", resp.Status, body.Description)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultRequestsPerSecond = 2.0
+	defaultBurst             = 2
+)
+
+// hostRateLimiter keeps one token-bucket limiter per host, so concurrent
+// fetches across many repos don't hammer e.g. github.com with hundreds of
+// simultaneous requests.
+type hostRateLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	rps      rate.Limit
+	burst    int
+}
+
+func newHostRateLimiter(rps float64, burst int) *hostRateLimiter {
+	if rps <= 0 {
+		rps = defaultRequestsPerSecond
+	}
+	if burst <= 0 {
+		burst = defaultBurst
+	}
+
+	return &hostRateLimiter{
+		limiters: make(map[string]*rate.Limiter),
+		rps:      rate.Limit(rps),
+		burst:    burst,
+	}
+}
+
+// wait blocks until rawUrl's host may be requested again.
+func (h *hostRateLimiter) wait(ctx context.Context, rawUrl string) error {
+	host := hostOf(rawUrl)
+	if host == "" {
+		return nil
+	}
+
+	h.mu.Lock()
+	limiter, ok := h.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(h.rps, h.burst)
+		h.limiters[host] = limiter
+	}
+	h.mu.Unlock()
+
+	return limiter.Wait(ctx)
+}
+
+func hostOf(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
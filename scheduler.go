@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/n-at/releases-stream-v2/filter"
+	"github.com/n-at/releases-stream-v2/notify"
+	"github.com/n-at/releases-stream-v2/store"
+)
+
+const (
+	defaultPollInterval     = 60 * time.Minute
+	defaultFetchConcurrency = 8
+)
+
+// Scheduler runs the poll/mail cycle on a ticker, in place of the external
+// cron job the one-shot version of this program used to rely on. A manual
+// run can be requested at any time via Trigger, e.g. from the /trigger HTTP
+// endpoint.
+type Scheduler struct {
+	settings    Settings
+	tpl         *template.Template
+	store       *store.Store
+	hostLimiter *hostRateLimiter
+	concurrency int
+	interval    time.Duration
+	trigger     chan struct{}
+}
+
+func NewScheduler(s Settings, tpl *template.Template, st *store.Store) *Scheduler {
+	interval := time.Duration(s.PollIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	concurrency := s.FetchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultFetchConcurrency
+	}
+
+	return &Scheduler{
+		settings:    s,
+		tpl:         tpl,
+		store:       st,
+		hostLimiter: newHostRateLimiter(s.FetchRequestsPerSec, concurrency),
+		concurrency: concurrency,
+		interval:    interval,
+		trigger:     make(chan struct{}, 1),
+	}
+}
+
+// Trigger requests an out-of-band cycle, coalescing with any already pending.
+func (sch *Scheduler) Trigger() {
+	select {
+	case sch.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run blocks, running one cycle immediately and then one per tick, until ctx
+// is cancelled (e.g. on SIGTERM/SIGINT).
+func (sch *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(sch.interval)
+	defer ticker.Stop()
+
+	sch.runCycle()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("scheduler stopping")
+			return
+		case <-ticker.C:
+			sch.runCycle()
+		case <-sch.trigger:
+			sch.runCycle()
+		}
+	}
+}
+
+func (sch *Scheduler) runCycle() {
+	s := sch.settings
+
+	notifiers, err := buildNotifiers(s)
+	if err != nil {
+		log.Printf("unable to build notifiers: %v", err)
+		return
+	}
+
+	sch.fetch()
+
+	for _, notifier := range notifiers {
+		sch.deliver(notifier)
+	}
+}
+
+// fetchResult is what one worker produces for one source; results are
+// collected out of order and sorted by index afterwards so processing (and
+// its logging) stays deterministic regardless of which fetch finished first.
+type fetchResult struct {
+	index        int
+	source       Source
+	releasesFeed []*gofeed.Item
+	err          error
+}
+
+// fetch polls every source through a bounded worker pool and records any new
+// releases in the store. Sources are fetched concurrently (each still
+// rate-limited per host), but processed in their original order.
+func (sch *Scheduler) fetch() {
+	sources, err := buildSources(sch.settings)
+	if err != nil {
+		log.Printf("unable to build sources: %v", err)
+		return
+	}
+
+	results := sch.fetchAll(sources)
+
+	slices.SortFunc(results, func(a, b fetchResult) int { return a.index - b.index })
+
+	for _, result := range results {
+		sch.process(result)
+	}
+}
+
+// fetchAll runs LatestReleases for every source across sch.concurrency
+// workers, each source's host rate-limited and transient failures retried
+// with exponential backoff.
+func (sch *Scheduler) fetchAll(sources []Source) []fetchResult {
+	jobs := make(chan int)
+	results := make([]fetchResult, len(sources))
+
+	var wg sync.WaitGroup
+	for w := 0; w < sch.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = sch.fetchOne(i, sources[i])
+			}
+		}()
+	}
+
+	for i := range sources {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return results
+}
+
+func (sch *Scheduler) fetchOne(index int, source Source) fetchResult {
+	log.Printf("reading releases for %s...", source.Key())
+
+	latestId, err := sch.store.LatestGUID(source.Key())
+	if err != nil {
+		return fetchResult{index: index, source: source, err: fmt.Errorf("unable to read latest id: %v", err)}
+	}
+
+	if err := sch.hostLimiter.wait(context.Background(), source.Repository().Url); err != nil {
+		return fetchResult{index: index, source: source, err: err}
+	}
+
+	var releasesFeed []*gofeed.Item
+	err = retryWithBackoff("fetch "+source.Key(), func() error {
+		var fetchErr error
+		releasesFeed, fetchErr = source.LatestReleases(latestId)
+		return fetchErr
+	})
+
+	return fetchResult{index: index, source: source, releasesFeed: releasesFeed, err: err}
+}
+
+func (sch *Scheduler) process(result fetchResult) {
+	source := result.source
+
+	if result.err != nil {
+		log.Printf("unable to read releases for %s: %v", source.Key(), result.err)
+		return
+	}
+
+	repository := source.Repository()
+	releasesFeed := result.releasesFeed
+
+	log.Printf("read releases for %s: %d", source.Key(), len(releasesFeed))
+
+	rule, err := resolveFilterRule(sch.settings, source.Key())
+	if err != nil {
+		log.Printf("unable to resolve filter for %s: %v", source.Key(), err)
+		return
+	}
+
+	seenToday, err := sch.store.CountSeenSince(source.Key(), time.Now().Add(-24*time.Hour))
+	if err != nil {
+		log.Printf("unable to count recent releases for %s: %v", source.Key(), err)
+		return
+	}
+
+	// baseline is the last release known before this batch, i.e. the release
+	// that precedes releasesFeed's oldest entry. A poll commonly turns up
+	// just one new release, so without this, bump filtering would never have
+	// anything to compare against and would let everything through.
+	baseline, hasBaseline, err := sch.store.LatestCursor(source.Key())
+	if err != nil {
+		log.Printf("unable to read baseline cursor for %s: %v", source.Key(), err)
+		return
+	}
+
+	for i, feedItem := range releasesFeed {
+		candidate := filter.Release{GUID: feedItem.GUID, Title: feedItem.Title, Categories: feedItem.Categories}
+
+		var previous filter.Release
+		if i+1 < len(releasesFeed) {
+			older := releasesFeed[i+1]
+			previous = filter.Release{GUID: older.GUID, Title: older.Title, Categories: older.Categories}
+		} else if hasBaseline {
+			previous = filter.Release{GUID: baseline.GUID, Title: baseline.Title}
+		}
+
+		if allowed, reason := filter.Allow(rule, candidate, previous, seenToday); !allowed {
+			log.Printf("filtered release %s/%s: %s", source.Key(), feedItem.GUID, reason)
+			continue
+		}
+
+		publishedAt := time.Now()
+		if feedItem.PublishedParsed != nil {
+			publishedAt = *feedItem.PublishedParsed
+		}
+
+		err := sch.store.MarkSeen(store.Release{
+			SourceKey:    source.Key(),
+			GUID:         feedItem.GUID,
+			RepoName:     repository.Name,
+			RepoFullName: repository.FullName,
+			RepoUrl:      repository.Url,
+			Title:        feedItem.Title,
+			Link:         feedItem.Link,
+			Content:      feedItem.Content,
+			PublishedAt:  publishedAt,
+		})
+		if err != nil {
+			log.Printf("unable to record release %s/%s: %v", source.Key(), feedItem.GUID, err)
+			continue
+		}
+
+		seenToday++
+	}
+
+	// The cursor must be the newest item of this batch, not whichever release
+	// happened to be recorded last: a single poll can seen-mark several
+	// releases with indistinguishable timestamps, and seen_at ordering can't
+	// tell them apart. releasesFeed is newest-first, so index 0 is it.
+	if len(releasesFeed) > 0 {
+		newest := releasesFeed[0]
+		cursor := store.Cursor{GUID: newest.GUID, Title: newest.Title}
+		if err := sch.store.SetLatestCursor(source.Key(), cursor); err != nil {
+			log.Printf("unable to update cursor for %s: %v", source.Key(), err)
+		}
+	}
+}
+
+// deliver sends every release not yet delivered through notifier, retrying
+// whatever failed to send on a previous cycle.
+func (sch *Scheduler) deliver(notifier notify.Notifier) {
+	pending, err := sch.store.PendingReleases(notifier.Name())
+	if err != nil {
+		log.Printf("unable to read pending releases for %s: %v", notifier.Name(), err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	releases := make([]Release, len(pending))
+	for i := range pending {
+		p := pending[i]
+		releases[i] = Release{
+			SourceKey:  p.SourceKey,
+			Repository: &Repository{Name: p.RepoName, FullName: p.RepoFullName, Url: p.RepoUrl},
+			FeedItem:   &gofeed.Item{GUID: p.GUID, Title: p.Title, Link: p.Link, Content: p.Content, PublishedParsed: &p.PublishedAt},
+		}
+	}
+
+	pages := splitReleasesByPages(releases)
+	log.Printf("got %d page(s) pending for %s", len(pages), notifier.Name())
+
+	for _, page := range pages {
+		pageRepos := extractPageRepositories(page)
+		sb := strings.Builder{}
+		err := sch.tpl.ExecuteTemplate(&sb, "mail.html", map[string]any{
+			"repositories": pageRepos,
+		})
+		if err != nil {
+			log.Printf("unable to render page: %v", err)
+			continue
+		}
+
+		notifyPage := notify.Page{
+			HTML:         sb.String(),
+			Repositories: toNotifyRepositoryReleases(pageRepos),
+		}
+
+		if err := notifier.Send(notifyPage); err != nil {
+			log.Printf("unable to send notification via %s: %v", notifier.Name(), err)
+			continue
+		}
+
+		for _, release := range page {
+			if err := sch.store.MarkDelivered(release.SourceKey, release.FeedItem.GUID, notifier.Name()); err != nil {
+				log.Printf("unable to mark %s/%s delivered via %s: %v", release.SourceKey, release.FeedItem.GUID, notifier.Name(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Source represents a single trackable item (a repository, a container image, a
+// feed URL, ...) that can produce a Repository description and a list of
+// releases newer than a given GUID.
+type Source interface {
+	// Key uniquely identifies the source across runs; used as the source key
+	// in the store.
+	Key() string
+	Repository() Repository
+	LatestReleases(latestId string) ([]*gofeed.Item, error)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ParseTrackedSource parses an entry from settings.json's "tracked" list.
+// Entries without a recognized prefix are rejected with an error; GitHub
+// repos come from getStarredRepos instead.
+func ParseTrackedSource(spec string, s Settings) (Source, error) {
+	switch {
+	case strings.HasPrefix(spec, "gitlab:"):
+		return newGitLabSource(strings.TrimPrefix(spec, "gitlab:"), s.GitLabToken), nil
+	case strings.HasPrefix(spec, "dockerhub:"):
+		return newDockerHubSource(strings.TrimPrefix(spec, "dockerhub:"), s.DockerHubToken), nil
+	case strings.HasPrefix(spec, "rss:"):
+		return newRssSource(strings.TrimPrefix(spec, "rss:")), nil
+	default:
+		return nil, fmt.Errorf("unrecognized tracked entry: %q", spec)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+type githubSource struct {
+	repository Repository
+}
+
+func newGithubSource(r Repository) Source {
+	return &githubSource{repository: r}
+}
+
+func (g *githubSource) Key() string {
+	return g.repository.FullName
+}
+
+func (g *githubSource) Repository() Repository {
+	return g.repository
+}
+
+func (g *githubSource) LatestReleases(latestId string) ([]*gofeed.Item, error) {
+	return getLatestReleases(g.repository, latestId)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+type gitlabSource struct {
+	projectPath string
+	token       string
+}
+
+func newGitLabSource(projectPath, token string) Source {
+	return &gitlabSource{projectPath: projectPath, token: token}
+}
+
+func (g *gitlabSource) Key() string {
+	return "gitlab:" + g.projectPath
+}
+
+func (g *gitlabSource) Repository() Repository {
+	r := Repository{
+		Name:     g.projectPath[strings.LastIndex(g.projectPath, "/")+1:],
+		FullName: g.projectPath,
+		Url:      "https://gitlab.com/" + g.projectPath,
+	}
+	r.Owner.Login = strings.SplitN(g.projectPath, "/", 2)[0]
+	return r
+}
+
+func (g *gitlabSource) LatestReleases(latestId string) ([]*gofeed.Item, error) {
+	encodedPath := strings.ReplaceAll(g.projectPath, "/", "%2F")
+	url := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/releases", encodedPath)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad response status: %v", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response: %v", err)
+	}
+
+	var releases []struct {
+		TagName     string    `json:"tag_name"`
+		Name        string    `json:"name"`
+		Description string    `json:"description"`
+		ReleasedAt  time.Time `json:"released_at"`
+		Links       struct {
+			Self string `json:"self"`
+		} `json:"_links"`
+	}
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("unable to parse JSON: %v", err)
+	}
+
+	var items []*gofeed.Item
+
+	for _, release := range releases {
+		if release.TagName == latestId {
+			break
+		}
+
+		releasedAt := release.ReleasedAt
+		items = append(items, &gofeed.Item{
+			GUID:            release.TagName,
+			Title:           release.Name,
+			Content:         release.Description,
+			Link:            release.Links.Self,
+			Published:       releasedAt.Format(time.RFC3339),
+			PublishedParsed: &releasedAt,
+		})
+	}
+
+	return items, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+type dockerhubSource struct {
+	image string
+	token string
+}
+
+func newDockerHubSource(image, token string) Source {
+	return &dockerhubSource{image: image, token: token}
+}
+
+func (d *dockerhubSource) Key() string {
+	return "dockerhub:" + d.image
+}
+
+func (d *dockerhubSource) Repository() Repository {
+	r := Repository{
+		Name:     d.image[strings.LastIndex(d.image, "/")+1:],
+		FullName: d.image,
+		Url:      "https://hub.docker.com/r/" + d.image,
+	}
+	r.Owner.Login = strings.SplitN(d.image, "/", 2)[0]
+	return r
+}
+
+func (d *dockerhubSource) LatestReleases(latestId string) ([]*gofeed.Item, error) {
+	// ordering=-last_updated: the "-" prefix is Docker Hub's convention for
+	// descending order. Without it the API returns tags oldest-first, which
+	// breaks the "stop at latestId" loop below (it assumes a newest-first
+	// feed, like every other source).
+	url := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/tags?page_size=100&ordering=-last_updated", d.image)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if d.token != "" {
+		req.Header.Set("Authorization", "Bearer "+d.token)
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad response status: %v", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response: %v", err)
+	}
+
+	var page struct {
+		Results []struct {
+			Name        string    `json:"name"`
+			Digest      string    `json:"digest"`
+			LastUpdated time.Time `json:"last_updated"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("unable to parse JSON: %v", err)
+	}
+
+	var items []*gofeed.Item
+
+	for _, tag := range page.Results {
+		guid := tag.Name + "@" + tag.Digest
+		if guid == latestId {
+			break
+		}
+
+		lastUpdated := tag.LastUpdated
+		items = append(items, &gofeed.Item{
+			GUID:            guid,
+			Title:           tag.Name,
+			Link:            "https://hub.docker.com/r/" + d.image + "/tags?name=" + tag.Name,
+			Published:       lastUpdated.Format(time.RFC3339),
+			PublishedParsed: &lastUpdated,
+		})
+	}
+
+	return items, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+type rssSource struct {
+	url string
+}
+
+func newRssSource(url string) Source {
+	return &rssSource{url: url}
+}
+
+func (r *rssSource) Key() string {
+	return "rss:" + r.url
+}
+
+func (r *rssSource) Repository() Repository {
+	return Repository{
+		Name:     r.url,
+		FullName: "rss:" + r.url,
+		Url:      r.url,
+	}
+}
+
+func (r *rssSource) LatestReleases(latestId string) ([]*gofeed.Item, error) {
+	fp := gofeed.NewParser()
+	feed, err := fp.ParseURL(r.url)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*gofeed.Item
+
+	for _, item := range feed.Items {
+		if item.GUID == latestId {
+			break
+		}
+		filtered = append(filtered, item)
+	}
+
+	return filtered, nil
+}
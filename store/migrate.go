@@ -0,0 +1,67 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// MigrateLatestJSON imports the old flat latest.json format (source key ->
+// newest seen GUID) on first run. It only has a GUID to go on, not the
+// release's title/link/content, so the imported row is seen-only metadata;
+// its purpose is solely to seed LatestGUID so already-notified releases
+// aren't re-fetched and re-sent. It is also marked delivered for the given
+// notifier names, matching the old behavior where a recorded "latest" id was
+// implicitly considered sent. Sources that already have a cursor in the store
+// are skipped, so later runs leave what the store has recorded untouched.
+func MigrateLatestJSON(s *Store, path string, notifiers []string) error {
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %v", path, err)
+	}
+
+	var ids map[string]string
+	if err := json.Unmarshal(bytes, &ids); err != nil {
+		return fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+
+	now := time.Now()
+
+	for sourceKey, guid := range ids {
+		if guid == "" {
+			continue
+		}
+
+		_, hasCursor, err := s.LatestCursor(sourceKey)
+		if err != nil {
+			return fmt.Errorf("unable to migrate %s: %v", sourceKey, err)
+		}
+		if hasCursor {
+			continue
+		}
+
+		if err := s.MarkSeen(Release{
+			SourceKey:   sourceKey,
+			GUID:        guid,
+			PublishedAt: now,
+		}); err != nil {
+			return fmt.Errorf("unable to migrate %s: %v", sourceKey, err)
+		}
+
+		if err := s.SetLatestCursor(sourceKey, Cursor{GUID: guid}); err != nil {
+			return fmt.Errorf("unable to migrate %s: %v", sourceKey, err)
+		}
+
+		for _, notifier := range notifiers {
+			if err := s.MarkDelivered(sourceKey, guid, notifier); err != nil {
+				return fmt.Errorf("unable to migrate %s: %v", sourceKey, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+// Package store persists seen releases and their per-notifier delivery
+// status in SQLite, so a notifier outage doesn't silently drop a release:
+// anything not yet marked delivered is picked up again on the next poll.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS releases (
+	source_key     TEXT NOT NULL,
+	guid           TEXT NOT NULL,
+	repo_name      TEXT NOT NULL,
+	repo_full_name TEXT NOT NULL,
+	repo_url       TEXT NOT NULL,
+	title          TEXT NOT NULL,
+	link           TEXT NOT NULL,
+	content        TEXT NOT NULL,
+	published_at   DATETIME,
+	seen_at        DATETIME NOT NULL,
+	PRIMARY KEY (source_key, guid)
+);
+
+CREATE TABLE IF NOT EXISTS deliveries (
+	source_key   TEXT NOT NULL,
+	guid         TEXT NOT NULL,
+	notifier     TEXT NOT NULL,
+	delivered_at DATETIME NOT NULL,
+	PRIMARY KEY (source_key, guid, notifier)
+);
+
+CREATE TABLE IF NOT EXISTS cursors (
+	source_key TEXT PRIMARY KEY,
+	guid       TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+`
+
+// Release is a release as recorded in the store: the source it came from,
+// the repository it belongs to, and enough of its content to re-render a
+// notification without re-fetching the feed.
+type Release struct {
+	SourceKey    string
+	GUID         string
+	RepoName     string
+	RepoFullName string
+	RepoUrl      string
+	Title        string
+	Link         string
+	Content      string
+	PublishedAt  time.Time
+}
+
+type Store struct {
+	db *sql.DB
+}
+
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open database: %v", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to apply schema: %v", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// MarkSeen records a release as seen. It is idempotent: re-marking an
+// already-seen (source, guid) pair is a no-op.
+func (s *Store) MarkSeen(r Release) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO releases
+			(source_key, guid, repo_name, repo_full_name, repo_url, title, link, content, published_at, seen_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.SourceKey, r.GUID, r.RepoName, r.RepoFullName, r.RepoUrl, r.Title, r.Link, r.Content, r.PublishedAt, time.Now())
+	return err
+}
+
+// MarkDelivered records that a release was successfully delivered through a
+// given notifier. Safe to call more than once for the same triple.
+func (s *Store) MarkDelivered(sourceKey, guid, notifier string) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO deliveries (source_key, guid, notifier, delivered_at)
+		VALUES (?, ?, ?, ?)`,
+		sourceKey, guid, notifier, time.Now())
+	return err
+}
+
+// Cursor is the most recently processed release for a source: used both as
+// the fetch cursor passed to Source.LatestReleases and as the baseline a new
+// release is compared against for semver-bump filtering.
+type Cursor struct {
+	GUID  string
+	Title string
+}
+
+// LatestCursor returns the cursor recorded for a source. ok is false if
+// nothing has been recorded yet.
+func (s *Store) LatestCursor(sourceKey string) (c Cursor, ok bool, err error) {
+	err = s.db.QueryRow(`
+		SELECT guid, title FROM cursors WHERE source_key = ?`,
+		sourceKey).Scan(&c.GUID, &c.Title)
+	if err == sql.ErrNoRows {
+		return Cursor{}, false, nil
+	}
+	return c, err == nil, err
+}
+
+// LatestGUID returns the cursor GUID for a source, used as the cursor passed
+// to Source.LatestReleases. Returns "" if nothing was recorded yet.
+func (s *Store) LatestGUID(sourceKey string) (string, error) {
+	c, ok, err := s.LatestCursor(sourceKey)
+	if err != nil || !ok {
+		return "", err
+	}
+	return c.GUID, nil
+}
+
+// SetLatestCursor records the cursor for a source. Callers should set this to
+// the newest release of a fetch batch once it has been processed, not derive
+// it from seen_at ordering: a single poll can record several releases with
+// indistinguishable timestamps, in an order that doesn't match recency.
+func (s *Store) SetLatestCursor(sourceKey string, c Cursor) error {
+	_, err := s.db.Exec(`
+		INSERT INTO cursors (source_key, guid, title, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (source_key) DO UPDATE SET guid = excluded.guid, title = excluded.title, updated_at = excluded.updated_at`,
+		sourceKey, c.GUID, c.Title, time.Now())
+	return err
+}
+
+// CountSeenSince returns how many releases for a source have been recorded
+// since the given time, used to enforce a per-repo daily rate limit.
+func (s *Store) CountSeenSince(sourceKey string, since time.Time) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM releases WHERE source_key = ? AND seen_at >= ?`,
+		sourceKey, since).Scan(&count)
+	return count, err
+}
+
+// PendingReleases returns every seen release that hasn't yet been delivered
+// through the given notifier, in the order they were recorded (seen_at).
+func (s *Store) PendingReleases(notifier string) ([]Release, error) {
+	rows, err := s.db.Query(`
+		SELECT r.source_key, r.guid, r.repo_name, r.repo_full_name, r.repo_url, r.title, r.link, r.content, r.published_at
+		FROM releases r
+		WHERE NOT EXISTS (
+			SELECT 1 FROM deliveries d
+			WHERE d.source_key = r.source_key AND d.guid = r.guid AND d.notifier = ?
+		)
+		ORDER BY r.seen_at ASC`, notifier)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var releases []Release
+	for rows.Next() {
+		var r Release
+		if err := rows.Scan(&r.SourceKey, &r.GUID, &r.RepoName, &r.RepoFullName, &r.RepoUrl, &r.Title, &r.Link, &r.Content, &r.PublishedAt); err != nil {
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+
+	return releases, rows.Err()
+}